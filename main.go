@@ -19,6 +19,9 @@ func main() {
 	r.GET("/home", func(c *gin.Context) {
 		HomeHandler(c)
 	})
+	r.GET("/crawl/stream", func(c *gin.Context) {
+		webcrawler.StreamCrawl(c)
+	})
 
 	// Start the server on port 8080
 	if err := r.Run(":8080"); err != nil {
@@ -0,0 +1,214 @@
+package webcrawler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/antchfx/xmlquery"
+)
+
+// newBodyReader returns a fresh reader over a response body so it can be
+// parsed independently by the HTML and XML handler pipelines.
+func newBodyReader(body []byte) io.Reader {
+	return bytes.NewReader(body)
+}
+
+// Request describes the page a handler is currently operating on, and
+// lets handlers enqueue further work without reaching back into the
+// crawl loop.
+type Request struct {
+	URL    string
+	Depth  int
+	Domain string
+
+	crawler *Crawler
+}
+
+// AbsoluteURL resolves href against the request's own URL.
+func (r *Request) AbsoluteURL(href string) string {
+	return resolveURL(r.URL, href)
+}
+
+// Visit enqueues url (resolved against the current page if relative) to
+// be crawled as part of the same domain's crawl, one level deeper than
+// the page it was found on.
+func (r *Request) Visit(u string) error {
+	abs := r.AbsoluteURL(u)
+	if abs == "" {
+		return fmt.Errorf("could not resolve URL %q against %q", u, r.URL)
+	}
+	return r.crawler.enqueue(r.Domain, abs, r.Depth+1)
+}
+
+// Response is the result of fetching a Request.
+type Response struct {
+	StatusCode int
+	Body       []byte
+	Request    *Request
+}
+
+// HTMLElement wraps a matched goquery selection together with the
+// Request and Response it was found on.
+type HTMLElement struct {
+	*goquery.Selection
+	Request  *Request
+	Response *Response
+}
+
+// Attr returns the value of the named attribute, or "" if absent.
+func (e *HTMLElement) Attr(name string) string {
+	val, _ := e.Selection.Attr(name)
+	return val
+}
+
+// XMLElement wraps a node matched by an OnXML xpath expression.
+type XMLElement struct {
+	node    *xmlquery.Node
+	Request *Request
+}
+
+// Text returns the node's inner text.
+func (e *XMLElement) Text() string {
+	return e.node.InnerText()
+}
+
+// Attr returns the value of the named attribute, or "" if absent.
+func (e *XMLElement) Attr(name string) string {
+	return e.node.SelectAttr(name)
+}
+
+type htmlCallback struct {
+	selector string
+	fn       func(*HTMLElement)
+}
+
+type xmlCallback struct {
+	xpath string
+	fn    func(*XMLElement)
+}
+
+// callbacks holds every handler an application has registered on a
+// Crawler via OnRequest/OnResponse/OnHTML/OnXML/OnError/OnScraped.
+type callbacks struct {
+	mu         sync.Mutex
+	onRequest  []func(*Request)
+	onResponse []func(*Response)
+	onHTML     []htmlCallback
+	onXML      []xmlCallback
+	onError    []func(*Response, error)
+	onScraped  []func(*Response)
+}
+
+// OnRequest registers a handler run before a URL is fetched.
+func (c *Crawler) OnRequest(f func(*Request)) {
+	cb := c.cbs()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onRequest = append(cb.onRequest, f)
+}
+
+// OnResponse registers a handler run after a URL is fetched successfully.
+func (c *Crawler) OnResponse(f func(*Response)) {
+	cb := c.cbs()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onResponse = append(cb.onResponse, f)
+}
+
+// OnHTML registers a handler run for every element matching selector in
+// an HTML response.
+func (c *Crawler) OnHTML(selector string, f func(*HTMLElement)) {
+	cb := c.cbs()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onHTML = append(cb.onHTML, htmlCallback{selector: selector, fn: f})
+}
+
+// OnXML registers a handler run for every node matching an xpath
+// expression, for responses that parse as XML.
+func (c *Crawler) OnXML(xpathExpr string, f func(*XMLElement)) {
+	cb := c.cbs()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onXML = append(cb.onXML, xmlCallback{xpath: xpathExpr, fn: f})
+}
+
+// OnError registers a handler run when fetching a URL fails.
+func (c *Crawler) OnError(f func(*Response, error)) {
+	cb := c.cbs()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onError = append(cb.onError, f)
+}
+
+// OnScraped registers a handler run once all other handlers have run for
+// a given Response.
+func (c *Crawler) OnScraped(f func(*Response)) {
+	cb := c.cbs()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onScraped = append(cb.onScraped, f)
+}
+
+// cbs lazily initializes the Crawler's callback registry.
+func (c *Crawler) cbs() *callbacks {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.callbacksState == nil {
+		c.callbacksState = &callbacks{}
+	}
+	return c.callbacksState
+}
+
+// fireRequest runs every registered OnRequest handler.
+func (c *Crawler) fireRequest(req *Request) {
+	cb := c.cbs()
+	for _, f := range cb.onRequest {
+		f(req)
+	}
+}
+
+// fireResponse runs every registered OnResponse, OnHTML, and OnXML
+// handler for resp, followed by every OnScraped handler.
+func (c *Crawler) fireResponse(resp *Response) {
+	cb := c.cbs()
+
+	for _, f := range cb.onResponse {
+		f(resp)
+	}
+
+	if len(cb.onHTML) > 0 {
+		if doc, err := goquery.NewDocumentFromReader(newBodyReader(resp.Body)); err == nil {
+			for _, hc := range cb.onHTML {
+				doc.Find(hc.selector).Each(func(i int, s *goquery.Selection) {
+					hc.fn(&HTMLElement{Selection: s, Request: resp.Request, Response: resp})
+				})
+			}
+		}
+	}
+
+	if len(cb.onXML) > 0 {
+		if doc, err := xmlquery.Parse(newBodyReader(resp.Body)); err == nil {
+			for _, xc := range cb.onXML {
+				for _, node := range xmlquery.Find(doc, xc.xpath) {
+					xc.fn(&XMLElement{node: node, Request: resp.Request})
+				}
+			}
+		}
+	}
+
+	for _, f := range cb.onScraped {
+		f(resp)
+	}
+}
+
+// fireError runs every registered OnError handler.
+func (c *Crawler) fireError(resp *Response, err error) {
+	cb := c.cbs()
+	for _, f := range cb.onError {
+		f(resp, err)
+	}
+}
@@ -0,0 +1,83 @@
+package webcrawler
+
+import (
+	"sync"
+	"time"
+)
+
+// Discovery is a single product URL found during a crawl, published on
+// the Crawler's discovery stream as soon as it's found rather than only
+// being appended to Storage under a lock.
+type Discovery struct {
+	Domain  string    `json:"domain"`
+	URL     string    `json:"url"`
+	FoundAt time.Time `json:"found_at"`
+}
+
+// discoveryBus fans a Discovery out to every current subscriber, e.g.
+// the streaming HTTP handler, a JSON file writer, and a metrics
+// collector, all watching the same crawl.
+type discoveryBus struct {
+	mu   sync.Mutex
+	subs []chan Discovery
+}
+
+// subscribe registers a new subscriber channel, buffered so a slow
+// consumer doesn't need to keep pace with every single discovery.
+func (b *discoveryBus) subscribe(buffer int) chan Discovery {
+	ch := make(chan Discovery, buffer)
+	b.mu.Lock()
+	b.subs = append(b.subs, ch)
+	b.mu.Unlock()
+	return ch
+}
+
+// publish fans d out to every subscriber. A subscriber whose buffer is
+// full is skipped rather than blocking the crawl.
+func (b *discoveryBus) publish(d Discovery) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.subs {
+		select {
+		case ch <- d:
+		default:
+		}
+	}
+}
+
+// closeAll closes and forgets every current subscriber, signaling that
+// no further discoveries will be published.
+func (b *discoveryBus) closeAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.subs {
+		close(ch)
+	}
+	b.subs = nil
+}
+
+// Discoveries returns a channel of the product URLs found from this
+// point on, so a caller can consume them as they're discovered instead
+// of waiting for the crawl to finish. The channel buffers 256
+// discoveries; one published while that buffer is full is dropped for
+// this subscriber (see publish). Call CloseDiscoveries once the
+// crawl is done to signal subscribers that the stream has ended.
+func (c *Crawler) Discoveries() <-chan Discovery {
+	return c.discoveries().subscribe(256)
+}
+
+// CloseDiscoveries closes every channel handed out by Discoveries,
+// signaling end of stream. Safe to call once after RunCrawler returns.
+func (c *Crawler) CloseDiscoveries() {
+	c.discoveries().closeAll()
+}
+
+// discoveries lazily initializes the Crawler's discovery bus.
+func (c *Crawler) discoveries() *discoveryBus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.discoveryBus == nil {
+		c.discoveryBus = &discoveryBus{}
+	}
+	return c.discoveryBus
+}
@@ -0,0 +1,231 @@
+package webcrawler
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+)
+
+// LimitRule configures polite-crawling behavior for a set of hosts,
+// modeled on Colly's LimitRule.
+type LimitRule struct {
+	// DomainGlob matches hostnames with '*' wildcards, e.g. "*.example.com".
+	DomainGlob string
+	// Delay is the minimum wait between two requests to a matched host.
+	Delay time.Duration
+	// RandomDelay adds an extra random duration in [0, RandomDelay) on
+	// top of Delay, to avoid looking like a metronome.
+	RandomDelay time.Duration
+	// Parallelism caps how many requests to a matched host may be in
+	// flight at once. Zero means 1.
+	Parallelism int
+	// RobotsTxt, when true, makes the crawler fetch and honor robots.txt
+	// for matched hosts before crawling them.
+	RobotsTxt bool
+}
+
+// hostBucket is the per-host scheduling state backing a LimitRule match:
+// a delay timed from the previous request and a worker semaphore, so a slow or
+// aggressively-limited host can't stall or get hammered by the rest of
+// the crawl.
+type hostBucket struct {
+	rule    LimitRule
+	sem     chan struct{}
+	mu      sync.Mutex
+	lastHit time.Time
+	robots  *robotsRules
+}
+
+func newHostBucket(rule LimitRule) *hostBucket {
+	parallelism := rule.Parallelism
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	return &hostBucket{
+		rule: rule,
+		sem:  make(chan struct{}, parallelism),
+	}
+}
+
+// Wait blocks until it is this host's turn to send a request, honoring
+// both the configured Delay/RandomDelay and the Parallelism cap. The
+// actual sleep happens outside b.mu so that, within the Parallelism cap,
+// other in-flight requests to this host aren't serialized behind it.
+func (b *hostBucket) Wait() {
+	b.sem <- struct{}{}
+
+	b.mu.Lock()
+	wait := b.rule.Delay
+	if b.rule.RandomDelay > 0 {
+		wait += time.Duration(rand.Int63n(int64(b.rule.RandomDelay)))
+	}
+	sleep := time.Duration(0)
+	if !b.lastHit.IsZero() {
+		if elapsed := time.Since(b.lastHit); elapsed < wait {
+			sleep = wait - elapsed
+		}
+	}
+	b.lastHit = time.Now().Add(sleep)
+	b.mu.Unlock()
+
+	if sleep > 0 {
+		time.Sleep(sleep)
+	}
+}
+
+// Release frees the slot acquired by Wait, to be called once the request
+// against this host has completed.
+func (b *hostBucket) Release() {
+	<-b.sem
+}
+
+// hostScheduler dispatches per-host buckets according to the first
+// matching LimitRule, falling back to a permissive default bucket for
+// hosts that match no rule.
+type hostScheduler struct {
+	mu                 sync.Mutex
+	rules              []LimitRule
+	buckets            map[string]*hostBucket
+	defaultParallelism int
+}
+
+func newHostScheduler() *hostScheduler {
+	return &hostScheduler{buckets: make(map[string]*hostBucket)}
+}
+
+func (s *hostScheduler) addRule(rule LimitRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append(s.rules, rule)
+}
+
+// bucketFor returns the hostBucket governing host, creating it from the
+// first matching LimitRule (or a sane default) on first contact.
+func (s *hostScheduler) bucketFor(host string) *hostBucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b, ok := s.buckets[host]; ok {
+		return b
+	}
+
+	parallelism := s.defaultParallelism
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	rule := LimitRule{Parallelism: parallelism}
+	for _, r := range s.rules {
+		if matchGlob(r.DomainGlob, host) {
+			rule = r
+			break
+		}
+	}
+	b := newHostBucket(rule)
+	s.buckets[host] = b
+	return b
+}
+
+// matchGlob matches hostnames against a glob pattern in path.Match
+// syntax ('*', '?' and '[...]' classes); "" and "*" match every host.
+func matchGlob(glob, host string) bool {
+	if glob == "" || glob == "*" {
+		return true
+	}
+	ok, err := path.Match(glob, host)
+	if err != nil {
+		return false
+	}
+	return ok
+}
+
+// Limit registers a LimitRule for hosts matching its DomainGlob. A host's
+// bucket is built from the rules registered when it is first contacted,
+// so a rule added later does not change an existing bucket. Crawl jobs
+// wait on the bucket's Delay and Parallelism; Fetch itself applies only
+// its RobotsTxt setting.
+func (c *Crawler) Limit(rule LimitRule) {
+	c.schedulerOnce()
+	c.scheduler.addRule(rule)
+}
+
+// schedulerOnce lazily initializes the Crawler's host scheduler so
+// callers that never call Limit still get a sane per-host default.
+func (c *Crawler) schedulerOnce() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.scheduler == nil {
+		c.scheduler = newHostScheduler()
+	}
+}
+
+// schemeAllowed reports whether link's scheme is in c.AllowedSchemes
+// (default http/https). It's checked both in enqueue, before a link
+// ever becomes a job, and again here in checkAllowed as a final guard at
+// fetch time.
+func (c *Crawler) schemeAllowed(link string) bool {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	allowed := c.AllowedSchemes
+	if len(allowed) == 0 {
+		allowed = []string{"http", "https"}
+	}
+	for _, s := range allowed {
+		if s == parsed.Scheme {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAllowed enforces AllowedSchemes and robots.txt for link, fetching
+// and caching robots.txt for its host on first contact when the matched
+// LimitRule has RobotsTxt enabled.
+func (c *Crawler) checkAllowed(link string) error {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return err
+	}
+
+	if !c.schemeAllowed(link) {
+		return fmt.Errorf("scheme %q not in AllowedSchemes", parsed.Scheme)
+	}
+
+	c.schedulerOnce()
+	bucket := c.scheduler.bucketFor(parsed.Host)
+
+	bucket.mu.Lock()
+	needsRobots := bucket.rule.RobotsTxt && bucket.robots == nil
+	bucket.mu.Unlock()
+	if needsRobots {
+		rules := fetchRobotsTxt(parsed.Scheme, parsed.Host, c.userAgent())
+		bucket.mu.Lock()
+		bucket.robots = rules
+		if rules.crawlDelay > 0 {
+			bucket.rule.Delay = rules.crawlDelay
+		}
+		bucket.mu.Unlock()
+	}
+
+	bucket.mu.Lock()
+	robots := bucket.robots
+	bucket.mu.Unlock()
+	if robots != nil && !robots.allowed(parsed.Path) {
+		return fmt.Errorf("disallowed by robots.txt: %s", link)
+	}
+
+	return nil
+}
+
+// userAgent returns the User-Agent the crawler identifies itself with.
+func (c *Crawler) userAgent() string {
+	if c.UserAgent != "" {
+		return c.UserAgent
+	}
+	return "webCrawler"
+}
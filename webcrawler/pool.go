@@ -0,0 +1,126 @@
+package webcrawler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// crawlJob is a single unit of work for the worker pool: visit url
+// (found while crawling domain) at the given BFS depth.
+type crawlJob struct {
+	url    string
+	domain string
+	depth  int
+}
+
+// startWorkers starts the crawler's fixed-size worker pool exactly once.
+// Each worker pulls jobs off c.jobs until the channel is closed by
+// shutdownWorkers, which RunCrawler and Close both call.
+func (c *Crawler) startWorkers() {
+	c.workersOnce.Do(func() {
+		c.jobs = make(chan crawlJob, 4096)
+		n := c.Concurrency
+		if n <= 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			go c.worker()
+		}
+	})
+}
+
+func (c *Crawler) worker() {
+	for job := range c.jobs {
+		c.processJob(job)
+		c.jobWG.Done()
+	}
+}
+
+// shutdownWorkers closes c.jobs so every worker goroutine started by
+// startWorkers exits, and is safe to call more than once (e.g. from both
+// RunCrawler and a deferred Close). Once called, this Crawler can no
+// longer crawl anything; construct a new one instead of reusing it.
+func (c *Crawler) shutdownWorkers() {
+	c.shutdownOnce.Do(func() {
+		if c.jobs != nil {
+			close(c.jobs)
+		}
+	})
+}
+
+// enqueue schedules url to be crawled for domain at the given depth,
+// enforcing AllowedSchemes and MaxDepth and deduping against URLs
+// already seen before the job ever reaches a worker. AllowedSchemes in
+// particular must be checked here, before MarkVisited: rejecting a
+// mailto:/javascript: link only once it reaches Fetch would already
+// have polluted the visited set and tied up a worker slot for it.
+func (c *Crawler) enqueue(domain, url string, depth int) error {
+	c.startWorkers()
+
+	if c.isStopped() {
+		return fmt.Errorf("crawl stopped, dropping %s", url)
+	}
+	if !c.schemeAllowed(url) {
+		return fmt.Errorf("scheme not in AllowedSchemes, skipping %s", url)
+	}
+	if c.MaxDepth > 0 && depth > c.MaxDepth {
+		return nil
+	}
+	if c.Storage.Visited(url) {
+		return nil
+	}
+	c.Storage.MarkVisited(url)
+
+	c.jobWG.Add(1)
+	select {
+	case c.jobs <- crawlJob{url: url, domain: domain, depth: depth}:
+		return nil
+	default:
+		c.jobWG.Done()
+		return fmt.Errorf("crawl queue full, dropping %s", url)
+	}
+}
+
+// processJob runs the full per-page pipeline for job: enforce MaxPages,
+// respect the per-host scheduler, fetch, and fire the registered
+// handlers.
+func (c *Crawler) processJob(job crawlJob) {
+	if c.isStopped() {
+		return
+	}
+
+	c.mu.Lock()
+	if c.pagesVisited == nil {
+		c.pagesVisited = make(map[string]int)
+	}
+	if c.MaxPages > 0 && c.pagesVisited[job.domain] >= c.MaxPages {
+		c.mu.Unlock()
+		return
+	}
+	c.pagesVisited[job.domain]++
+	c.mu.Unlock()
+
+	if c.state != nil && !c.state.Stale(job.url, c.stateTTL) {
+		log.Printf("Skipping already-crawled (state DB): %s\n", job.url)
+		return
+	}
+
+	host := urlHost(job.url)
+	bucket := c.scheduler.bucketFor(host)
+	bucket.Wait()
+	defer bucket.Release()
+
+	req := &Request{URL: job.url, Depth: job.depth, Domain: job.domain, crawler: c}
+	c.fireRequest(req)
+
+	log.Printf("Crawling: %s\n", job.url)
+	html, err := c.Fetch(job.url)
+	if err != nil {
+		log.Printf("Error fetching %s: %v\n", job.url, err)
+		c.fireError(&Response{Request: req}, err)
+		return
+	}
+
+	c.fireResponse(&Response{StatusCode: http.StatusOK, Body: []byte(html), Request: req})
+}
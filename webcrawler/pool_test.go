@@ -0,0 +1,18 @@
+package webcrawler
+
+import "testing"
+
+func TestEnqueueRejectsAfterStop(t *testing.T) {
+	c := CreateNewCrawler([]string{"example.com"}, 1)
+	defer c.Close()
+
+	c.Stop()
+
+	url := "https://example.com/a"
+	if err := c.enqueue("example.com", url, 0); err == nil {
+		t.Fatal("enqueue should reject new jobs once the crawl has been stopped")
+	}
+	if c.Storage.Visited(url) {
+		t.Errorf("a stopped crawl must not mark urls visited for jobs it never queues")
+	}
+}
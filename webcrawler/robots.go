@@ -0,0 +1,135 @@
+package webcrawler
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// robotsRules is a minimal parsed robots.txt: the Disallow/Allow paths
+// that apply to us, plus any Crawl-delay.
+type robotsRules struct {
+	disallow   []string
+	allow      []string
+	crawlDelay time.Duration
+}
+
+// fetchRobotsTxt fetches and parses robots.txt for host. A fetch failure
+// (no robots.txt, network error, etc.) is treated as "everything
+// allowed", matching standard crawler behavior.
+func fetchRobotsTxt(scheme, host, userAgent string) *robotsRules {
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s://%s/robots.txt", scheme, host), nil)
+	if err != nil {
+		return &robotsRules{}
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return &robotsRules{}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &robotsRules{}
+	}
+
+	return parseRobotsTxt(resp.Body, userAgent)
+}
+
+// parseRobotsTxt implements just enough of the robots.txt spec to honor
+// a User-agent: * or matching-product-specific block's Disallow, Allow,
+// and Crawl-delay directives.
+func parseRobotsTxt(r interface{ Read([]byte) (int, error) }, userAgent string) *robotsRules {
+	rules := &robotsRules{}
+	scanner := bufio.NewScanner(r)
+
+	applies := false
+	matchedSpecific := false
+	// inAgentBlock tracks whether the line just scanned was itself a
+	// User-agent line: per the robots.txt grammar, consecutive
+	// User-agent lines name one shared group, while a User-agent line
+	// that follows a rule line starts a brand new group. Any rule line
+	// closes the block that's currently being named.
+	inAgentBlock := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+
+		switch key {
+		case "user-agent":
+			if !inAgentBlock {
+				// A new group: until one of its User-agent lines names
+				// us, its rules don't apply, no matter which group
+				// matched before it — the most specific match always
+				// wins, and a later group (whether "*" or another
+				// specific agent) can never add to or override it.
+				applies = false
+				inAgentBlock = true
+			}
+			agent := strings.ToLower(value)
+			if agent == "*" {
+				if !matchedSpecific {
+					applies = true
+				}
+			} else if strings.Contains(strings.ToLower(userAgent), agent) {
+				applies = true
+				matchedSpecific = true
+				rules.disallow = nil
+				rules.allow = nil
+				rules.crawlDelay = 0
+			}
+		case "disallow":
+			inAgentBlock = false
+			if applies && value != "" {
+				rules.disallow = append(rules.disallow, value)
+			}
+		case "allow":
+			inAgentBlock = false
+			if applies && value != "" {
+				rules.allow = append(rules.allow, value)
+			}
+		case "crawl-delay":
+			inAgentBlock = false
+			if applies {
+				if secs, err := strconv.ParseFloat(value, 64); err == nil {
+					rules.crawlDelay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	return rules
+}
+
+// allowed reports whether path may be fetched under these rules. The
+// longest matching Allow/Disallow rule wins, as per the de-facto
+// robots.txt standard.
+func (r *robotsRules) allowed(reqPath string) bool {
+	longestMatch := -1
+	isAllowed := true
+
+	check := func(prefixes []string, allow bool) {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(reqPath, prefix) && len(prefix) > longestMatch {
+				longestMatch = len(prefix)
+				isAllowed = allow
+			}
+		}
+	}
+	check(r.disallow, false)
+	check(r.allow, true)
+
+	return isAllowed
+}
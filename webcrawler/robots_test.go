@@ -0,0 +1,67 @@
+package webcrawler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRobotsTxtMostSpecificGroupWins(t *testing.T) {
+	body := "User-agent: webCrawler\n" +
+		"Disallow: /a\n" +
+		"User-agent: *\n" +
+		"Disallow: /b\n"
+
+	rules := parseRobotsTxt(strings.NewReader(body), "webCrawler")
+
+	if !rules.allowed("/b") {
+		t.Errorf("/b should be allowed: it's only disallowed in the unmatched wildcard group")
+	}
+	if rules.allowed("/a") {
+		t.Errorf("/a should be disallowed: it's disallowed in our matched specific group")
+	}
+}
+
+func TestParseRobotsTxtSpecificGroupNotOverriddenByLaterSpecificGroup(t *testing.T) {
+	body := "User-agent: webCrawler\n" +
+		"Disallow: /private\n" +
+		"\n" +
+		"User-agent: BadBot\n" +
+		"Disallow: /\n"
+
+	rules := parseRobotsTxt(strings.NewReader(body), "webCrawler")
+
+	if !rules.allowed("/products") {
+		t.Errorf("/products should be allowed: the BadBot group doesn't name us and must not apply")
+	}
+	if rules.allowed("/private") {
+		t.Errorf("/private should be disallowed: it's disallowed in our matched specific group")
+	}
+}
+
+func TestParseRobotsTxtFallsBackToWildcard(t *testing.T) {
+	body := "User-agent: *\n" +
+		"Disallow: /private\n"
+
+	rules := parseRobotsTxt(strings.NewReader(body), "webCrawler")
+
+	if rules.allowed("/private") {
+		t.Errorf("/private should be disallowed by the wildcard group")
+	}
+	if !rules.allowed("/public") {
+		t.Errorf("/public should be allowed")
+	}
+}
+
+func TestRobotsRulesAllowedLongestMatchWins(t *testing.T) {
+	rules := &robotsRules{
+		disallow: []string{"/a"},
+		allow:    []string{"/a/b"},
+	}
+
+	if !rules.allowed("/a/b/c") {
+		t.Errorf("/a/b/c should be allowed: /a/b is the longer, more specific match")
+	}
+	if rules.allowed("/a/x") {
+		t.Errorf("/a/x should be disallowed: only /a matches, not the more specific /a/b allow")
+	}
+}
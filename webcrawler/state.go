@@ -0,0 +1,96 @@
+package webcrawler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var urlStateBucket = []byte("url_state")
+
+// urlState records what happened the last time a URL was fetched, so a
+// restarted crawl can skip work it already did.
+type urlState struct {
+	Status      int       `json:"status"`
+	FetchedAt   time.Time `json:"fetched_at"`
+	ContentHash string    `json:"content_hash"`
+}
+
+// crawlStateDB is a BoltDB-backed map of URL -> urlState used to make
+// crawls restartable.
+type crawlStateDB struct {
+	db *bolt.DB
+}
+
+// openCrawlState opens (creating if necessary) the BoltDB file that
+// backs a crawl's state directory.
+func openCrawlState(dir string) (*crawlStateDB, error) {
+	db, err := bolt.Open(filepath.Join(dir, "crawl-state.db"), 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(urlStateBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &crawlStateDB{db: db}, nil
+}
+
+// Get returns the recorded state for url, if any.
+func (s *crawlStateDB) Get(url string) (urlState, bool) {
+	var st urlState
+	found := false
+	s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(urlStateBucket).Get([]byte(url))
+		if raw == nil {
+			return nil
+		}
+		if err := json.Unmarshal(raw, &st); err == nil {
+			found = true
+		}
+		return nil
+	})
+	return st, found
+}
+
+// Put records that url was fetched with the given status and body.
+func (s *crawlStateDB) Put(url string, status int, body []byte) error {
+	sum := sha256.Sum256(body)
+	st := urlState{
+		Status:      status,
+		FetchedAt:   time.Now(),
+		ContentHash: hex.EncodeToString(sum[:]),
+	}
+	raw, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(urlStateBucket).Put([]byte(url), raw)
+	})
+}
+
+// Stale reports whether url's recorded state is missing or older than ttl
+// and should therefore be re-fetched. A zero ttl means "never stale".
+func (s *crawlStateDB) Stale(url string, ttl time.Duration) bool {
+	st, found := s.Get(url)
+	if !found {
+		return true
+	}
+	if ttl <= 0 {
+		return false
+	}
+	return time.Since(st.FetchedAt) > ttl
+}
+
+func (s *crawlStateDB) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,67 @@
+package webcrawler
+
+import "sync"
+
+// Storage is the persistence boundary a Crawler depends on for its
+// visited-URL set and discovered product URLs. Swapping implementations
+// changes how (and whether) crawl state is shared: in-memory for a
+// single one-shot run, BoltDB for a resumable single-node crawl, Redis
+// for multiple Crawler processes sharing one crawl.
+type Storage interface {
+	// Visited reports whether url has already been marked visited.
+	Visited(url string) bool
+	// MarkVisited records that url has been visited.
+	MarkVisited(url string)
+	// AddProduct records url as a discovered product page for domain.
+	AddProduct(domain, url string)
+	// Products returns every product URL recorded for domain.
+	Products(domain string) []string
+	// Close releases any resources held by the backend.
+	Close() error
+}
+
+// memoryStorage is the default Storage: plain maps guarded by a mutex,
+// scoped to a single process and a single Crawler.
+type memoryStorage struct {
+	mu       sync.Mutex
+	visited  map[string]bool
+	products map[string][]string
+}
+
+// NewMemoryStorage returns a Storage backed by in-process maps.
+func NewMemoryStorage() Storage {
+	return &memoryStorage{
+		visited:  make(map[string]bool),
+		products: make(map[string][]string),
+	}
+}
+
+func (s *memoryStorage) Visited(url string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.visited[url]
+}
+
+func (s *memoryStorage) MarkVisited(url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.visited[url] = true
+}
+
+func (s *memoryStorage) AddProduct(domain, url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.products[domain] = append(s.products[domain], url)
+}
+
+func (s *memoryStorage) Products(domain string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, len(s.products[domain]))
+	copy(out, s.products[domain])
+	return out
+}
+
+func (s *memoryStorage) Close() error {
+	return nil
+}
@@ -0,0 +1,96 @@
+package webcrawler
+
+import (
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltVisitedBucket = []byte("visited")
+	boltProductBucket = []byte("products")
+)
+
+// boltStorage is a Storage backed by a BoltDB file, so a crawl's visited
+// set and discovered products survive a restart on the same node.
+type boltStorage struct {
+	db *bolt.DB
+}
+
+// NewBoltStorage opens (creating if necessary) a BoltDB-backed Storage
+// at path.
+func NewBoltStorage(path string) (Storage, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening bolt storage %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(boltVisitedBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(boltProductBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltStorage{db: db}, nil
+}
+
+func (s *boltStorage) Visited(url string) bool {
+	found := false
+	s.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(boltVisitedBucket).Get([]byte(url)) != nil
+		return nil
+	})
+	return found
+}
+
+func (s *boltStorage) MarkVisited(url string) {
+	s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltVisitedBucket).Put([]byte(url), []byte{1})
+	})
+}
+
+// AddProduct appends url to domain's product list, stored as
+// newline-delimited URLs under the domain key.
+func (s *boltStorage) AddProduct(domain, url string) {
+	s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltProductBucket)
+		existing := b.Get([]byte(domain))
+		updated := append(append([]byte{}, existing...), []byte(url+"\n")...)
+		return b.Put([]byte(domain), updated)
+	})
+}
+
+func (s *boltStorage) Products(domain string) []string {
+	var urls []string
+	s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(boltProductBucket).Get([]byte(domain))
+		urls = splitLines(raw)
+		return nil
+	})
+	return urls
+}
+
+func (s *boltStorage) Close() error {
+	return s.db.Close()
+}
+
+// splitLines splits newline-delimited bytes into a slice of non-empty
+// strings.
+func splitLines(raw []byte) []string {
+	var out []string
+	start := 0
+	for i, b := range raw {
+		if b == '\n' {
+			if i > start {
+				out = append(out, string(raw[start:i]))
+			}
+			start = i + 1
+		}
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package webcrawler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStorage is a Storage backed by Redis, so multiple Crawler
+// processes can share one visited set and product-URL sink via
+// SADD/SISMEMBER.
+type redisStorage struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStorage returns a Storage backed by the Redis server at addr.
+// prefix namespaces its keys (e.g. "webcrawler") so a shared Redis
+// instance can host more than one crawl.
+func NewRedisStorage(addr, prefix string) (Storage, error) {
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, fmt.Errorf("connecting to redis at %s: %w", addr, err)
+	}
+	return &redisStorage{client: client, prefix: prefix}, nil
+}
+
+func (s *redisStorage) visitedKey() string {
+	return s.prefix + ":visited"
+}
+
+func (s *redisStorage) productsKey(domain string) string {
+	return s.prefix + ":products:" + domain
+}
+
+func (s *redisStorage) Visited(url string) bool {
+	ok, err := s.client.SIsMember(context.Background(), s.visitedKey(), url).Result()
+	if err != nil {
+		return false
+	}
+	return ok
+}
+
+func (s *redisStorage) MarkVisited(url string) {
+	s.client.SAdd(context.Background(), s.visitedKey(), url)
+}
+
+func (s *redisStorage) AddProduct(domain, url string) {
+	s.client.SAdd(context.Background(), s.productsKey(domain), url)
+}
+
+func (s *redisStorage) Products(domain string) []string {
+	urls, err := s.client.SMembers(context.Background(), s.productsKey(domain)).Result()
+	if err != nil {
+		return nil
+	}
+	return urls
+}
+
+func (s *redisStorage) Close() error {
+	return s.client.Close()
+}
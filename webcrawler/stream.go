@@ -0,0 +1,91 @@
+package webcrawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamCrawl starts a crawl for ?domain= and streams each discovered
+// product URL as a Server-Sent Event as soon as it's found, instead of
+// blocking until the crawl finishes and serving a JSON file. A final
+// "done" event carries summary stats.
+func StreamCrawl(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "domain query param is required"})
+		return
+	}
+
+	crawler := CreateNewCrawler([]string{domain}, 20)
+	crawler.Limit(LimitRule{
+		DomainGlob:  "*",
+		Delay:       10 * time.Millisecond,
+		Parallelism: 20,
+		RobotsTxt:   true,
+	})
+
+	storage, err := selectStorage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	crawler.Storage = storage
+
+	discoveries := crawler.Discoveries()
+	start := time.Now()
+
+	// RunCrawler shuts the worker pool down itself once the crawl
+	// finishes (see shutdownWorkers), so there's no leak even though we
+	// never call crawler.Close() here; doing so from this goroutine
+	// while RunCrawler might still be running (e.g. on early client
+	// disconnect below) would close c.jobs out from under workers still
+	// processing it. crawlDone closes once RunCrawler has actually
+	// returned, so storage isn't closed out from under a crawl that's
+	// still writing to it.
+	crawlDone := make(chan struct{})
+	go func() {
+		defer close(crawlDone)
+		crawler.RunCrawler()
+		crawler.CloseDiscoveries()
+	}()
+	defer func() {
+		<-crawlDone
+		storage.Close()
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case d, ok := <-discoveries:
+			if !ok {
+				// Read the count back from Storage rather than counting
+				// events this subscriber received: discoveryBus.publish
+				// drops events on a full subscriber buffer, so a local
+				// tally could silently undercount against a slow client.
+				summary, _ := json.Marshal(gin.H{
+					"domain":         domain,
+					"products_found": len(storage.Products(domain)),
+					"elapsed":        time.Since(start).String(),
+				})
+				fmt.Fprintf(w, "event: done\ndata: %s\n\n", summary)
+				return false
+			}
+			payload, _ := json.Marshal(d)
+			fmt.Fprintf(w, "event: product\ndata: %s\n\n", payload)
+			return true
+		case <-c.Request.Context().Done():
+			// The client is gone: stop the crawl instead of letting it
+			// run to completion unobserved in the background.
+			crawler.Stop()
+			return false
+		}
+	})
+}
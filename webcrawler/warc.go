@@ -0,0 +1,112 @@
+package webcrawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// warcWriter serializes HTTP request/response pairs as gzip-compressed
+// WARC/1.0 records. It is safe for concurrent use.
+type warcWriter struct {
+	mu   sync.Mutex
+	file *os.File
+	gz   *gzip.Writer
+}
+
+// newWarcWriter opens (or creates) path and writes the leading warcinfo
+// record that identifies this crawl.
+func newWarcWriter(path string) (*warcWriter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	w := &warcWriter{file: f, gz: gzip.NewWriter(f)}
+	if err := w.writeWarcInfo(); err != nil {
+		w.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *warcWriter) writeWarcInfo() error {
+	body := []byte("software: webCrawler\r\nformat: WARC File Format 1.0\r\n")
+	return w.writeRecord("warcinfo", "", body, "application/warc-fields")
+}
+
+// WriteExchange archives a single request/response pair as a pair of
+// WARC records (request, response) sharing a target URI.
+func (w *warcWriter) WriteExchange(targetURL string, req *http.Request, resp *http.Response, body []byte) error {
+	if req != nil {
+		reqBytes, err := httputil.DumpRequestOut(req, true)
+		if err == nil {
+			if err := w.writeRecord("request", targetURL, reqBytes, "application/http; msgtype=request"); err != nil {
+				return err
+			}
+		}
+	}
+
+	var respBuf bytes.Buffer
+	fmt.Fprintf(&respBuf, "HTTP/1.1 %d %s\r\n", resp.StatusCode, http.StatusText(resp.StatusCode))
+	resp.Header.Write(&respBuf)
+	respBuf.WriteString("\r\n")
+	respBuf.Write(body)
+
+	return w.writeRecord("response", targetURL, respBuf.Bytes(), "application/http; msgtype=response")
+}
+
+func (w *warcWriter) writeRecord(recordType, targetURI string, body []byte, contentType string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var header bytes.Buffer
+	header.WriteString("WARC/1.0\r\n")
+	fmt.Fprintf(&header, "WARC-Type: %s\r\n", recordType)
+	fmt.Fprintf(&header, "WARC-Record-ID: <urn:uuid:%s>\r\n", uuid.New().String())
+	fmt.Fprintf(&header, "WARC-Date: %s\r\n", time.Now().UTC().Format(time.RFC3339))
+	if targetURI != "" {
+		fmt.Fprintf(&header, "WARC-Target-URI: %s\r\n", targetURI)
+	}
+	fmt.Fprintf(&header, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(&header, "Content-Length: %d\r\n", len(body))
+	header.WriteString("\r\n")
+
+	if _, err := w.gz.Write(header.Bytes()); err != nil {
+		return err
+	}
+	if _, err := w.gz.Write(body); err != nil {
+		return err
+	}
+	_, err := w.gz.Write([]byte("\r\n\r\n"))
+	return err
+}
+
+// Close flushes and closes the underlying WARC file.
+func (w *warcWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.gz.Close(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// drainAndRestore reads resp.Body fully so it can be archived, then
+// replaces it with a fresh reader so downstream parsing still works.
+func drainAndRestore(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
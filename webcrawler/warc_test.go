@@ -0,0 +1,78 @@
+package webcrawler
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWarcWriterRecordFraming(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.warc.gz")
+
+	w, err := newWarcWriter(path)
+	if err != nil {
+		t.Fatalf("newWarcWriter: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/product/42", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/html"}},
+	}
+	body := []byte("<html>hi</html>")
+
+	if err := w.WriteExchange("https://example.com/product/42", req, resp, body); err != nil {
+		t.Fatalf("WriteExchange: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("reading decompressed WARC: %v", err)
+	}
+	content := string(raw)
+
+	records := strings.Count(content, "WARC/1.0\r\n")
+	if records != 3 {
+		t.Fatalf("got %d WARC records, want 3 (warcinfo, request, response)", records)
+	}
+
+	for _, want := range []string{
+		"WARC-Type: warcinfo",
+		"WARC-Type: request",
+		"WARC-Type: response",
+		"WARC-Target-URI: https://example.com/product/42",
+		"Content-Type: application/http; msgtype=request",
+		"Content-Type: application/http; msgtype=response",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("WARC output missing %q", want)
+		}
+	}
+
+	if strings.Contains(content, "WARC-Record-ID: <urn:uuid:>") {
+		t.Errorf("WARC-Record-ID was emitted empty")
+	}
+	if !strings.Contains(content, "WARC-Record-ID: <urn:uuid:") {
+		t.Errorf("WARC-Record-ID not in the expected <urn:uuid:...> form")
+	}
+}
@@ -19,22 +19,152 @@ import (
 // Crawler holds the configuration and state of the crawler.
 type Crawler struct {
 	Domains     []string
-	RateLimit   time.Duration
 	Concurrency int
-	ProductURLs map[string][]string
 	mu          sync.Mutex
-	semaphore   chan struct{}
+
+	// Storage holds the visited-URL set and discovered product URLs.
+	// Defaults to an in-memory Storage; set before RunCrawler to share
+	// crawl state across restarts (NewBoltStorage) or processes
+	// (NewRedisStorage).
+	Storage Storage
+
+	warc     *warcWriter
+	state    *crawlStateDB
+	stateTTL time.Duration
+
+	// UserAgent is sent on every request; defaults to "webCrawler".
+	UserAgent string
+	// AllowedSchemes restricts which URL schemes are enqueued; defaults
+	// to http and https.
+	AllowedSchemes []string
+
+	scheduler *hostScheduler
+
+	callbacksState *callbacks
+
+	// MaxDepth caps how many links deep a crawl follows from a domain's
+	// root URL. Zero means unlimited.
+	MaxDepth int
+	// MaxPages caps how many pages are fetched per domain. Zero means
+	// unlimited.
+	MaxPages int
+
+	jobs         chan crawlJob
+	jobWG        sync.WaitGroup
+	workersOnce  sync.Once
+	shutdownOnce sync.Once
+	pagesVisited map[string]int
+	stopped      bool
+
+	discoveryBus *discoveryBus
 }
 
-// NewCrawler creates a new instance of the Crawler.
-func CreateNewCrawler(domains []string, rateLimit time.Duration, concurrency int) *Crawler {
-	return &Crawler{
+// CreateNewCrawler creates a new instance of the Crawler. Per-host pacing is
+// configured separately via Limit; this only sets the default
+// parallelism applied to hosts that match no explicit LimitRule.
+//
+// The returned Crawler has one built-in OnHTML("a[href]", ...) handler
+// that reproduces the crawler's original behavior: links matching
+// IsProductURL are recorded, everything else on the same domain is
+// visited. Register additional OnRequest/OnResponse/OnHTML/OnXML
+// handlers before calling RunCrawler to extend that behavior; the
+// built-in handler stays registered and runs alongside them.
+func CreateNewCrawler(domains []string, concurrency int) *Crawler {
+	c := &Crawler{
 		Domains:     domains,
-		RateLimit:   rateLimit,
 		Concurrency: concurrency,
-		ProductURLs: make(map[string][]string),
-		semaphore:   make(chan struct{}, concurrency),
+		Storage:     NewMemoryStorage(),
 	}
+	c.schedulerOnce()
+	c.scheduler.defaultParallelism = concurrency
+	c.registerDefaultHandlers()
+	return c
+}
+
+// registerDefaultHandlers wires up the built-in link-following behavior
+// that used to be hard-coded in CrawlDomain.
+func (c *Crawler) registerDefaultHandlers() {
+	c.OnHTML("a[href]", func(e *HTMLElement) {
+		href := e.Attr("href")
+		if href == "" {
+			return
+		}
+		link := e.Request.AbsoluteURL(href)
+		if !isSameDomain("https://"+e.Request.Domain, link) {
+			return
+		}
+		if c.IsProductURL(link) {
+			c.Storage.AddProduct(e.Request.Domain, link)
+			c.discoveries().publish(Discovery{Domain: e.Request.Domain, URL: link, FoundAt: time.Now()})
+			return
+		}
+		if err := e.Request.Visit(link); err != nil {
+			log.Printf("Error queuing %s: %v\n", link, err)
+		}
+	})
+}
+
+// ArchiveTo enables archival mode: every fetched page is written as a
+// request/response pair to a gzip-compressed WARC file at warcPath, and
+// crawl progress is tracked in a resumable BoltDB-backed state DB under
+// statePath so a restarted crawl can skip URLs it already fetched. ttl
+// controls how long a recorded fetch remains valid before it is retried;
+// a zero ttl means previously-fetched URLs are never retried.
+func (c *Crawler) ArchiveTo(warcPath, statePath string, ttl time.Duration) error {
+	if err := os.MkdirAll(statePath, 0755); err != nil {
+		return fmt.Errorf("creating state dir %s: %w", statePath, err)
+	}
+	state, err := openCrawlState(statePath)
+	if err != nil {
+		return fmt.Errorf("opening crawl state: %w", err)
+	}
+	warc, err := newWarcWriter(warcPath)
+	if err != nil {
+		state.Close()
+		return fmt.Errorf("opening warc file: %w", err)
+	}
+	c.state = state
+	c.warc = warc
+	c.stateTTL = ttl
+	return nil
+}
+
+// Close shuts down the worker pool (see shutdownWorkers) and releases
+// any resources opened by ArchiveTo. Call it once a crawl is done,
+// whether that crawl ran via RunCrawler/CrawlDomain or was abandoned
+// early.
+func (c *Crawler) Close() error {
+	c.shutdownWorkers()
+
+	var err error
+	if c.warc != nil {
+		err = c.warc.Close()
+	}
+	if c.state != nil {
+		if cerr := c.state.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Stop requests that the crawl wind down as soon as possible: no new
+// jobs are accepted and jobs still queued are dropped rather than
+// fetched (a fetch already under way runs to completion), so a
+// caller no longer interested in the results (e.g. a disconnected HTTP
+// client) doesn't have to wait for RunCrawler to exhaust every
+// reachable link before it returns. Safe to call more than once.
+func (c *Crawler) Stop() {
+	c.mu.Lock()
+	c.stopped = true
+	c.mu.Unlock()
+}
+
+// isStopped reports whether Stop has been called.
+func (c *Crawler) isStopped() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stopped
 }
 
 // IsProductURL determines if a URL is likely a product page.
@@ -43,15 +173,42 @@ func (c *Crawler) IsProductURL(link string) bool {
 	return productRegex.MatchString(link)
 }
 
-// Fetch fetches the HTML content of a given URL.
+// Fetch fetches the HTML content of a given URL. When archiving is
+// enabled (see ArchiveTo), the raw request/response pair is also routed
+// through the WARC writer and the state DB is updated.
 func (c *Crawler) Fetch(link string) (string, error) {
+	if err := c.checkAllowed(link); err != nil {
+		return "", err
+	}
+
 	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(link)
+	req, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", c.userAgent())
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
+	if c.warc != nil {
+		raw, err := drainAndRestore(resp)
+		if err != nil {
+			return "", err
+		}
+		if err := c.warc.WriteExchange(link, req, resp, raw); err != nil {
+			log.Printf("Error archiving %s to WARC: %v\n", link, err)
+		}
+		if c.state != nil {
+			if err := c.state.Put(link, resp.StatusCode, raw); err != nil {
+				log.Printf("Error updating crawl state for %s: %v\n", link, err)
+			}
+		}
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("failed to fetch %s: HTTP %d", link, resp.StatusCode)
 	}
@@ -86,55 +243,41 @@ func (c *Crawler) ParseLinks(html, baseURL string) ([]string, error) {
 	return links, nil
 }
 
-// CrawlDomain discovers product URLs for a single domain.
+// CrawlDomain seeds the worker pool with domain's root URL and blocks
+// until every job reachable from it (including pages enqueued by
+// handlers via Request.Visit) has been processed. The actual per-page
+// work happens in the shared worker pool started by RunCrawler/
+// startWorkers; see pool.go.
 func (c *Crawler) CrawlDomain(domain string) {
+	c.startWorkers()
 	baseURL := "https://" + domain
-	visited := make(map[string]bool)
-	toVisit := []string{baseURL}
-
-	for len(toVisit) > 0 {
-		c.semaphore <- struct{}{}
-		currentURL := toVisit[0]
-		toVisit = toVisit[1:]
-
-		if visited[currentURL] {
-			<-c.semaphore
-			continue
-		}
-		visited[currentURL] = true
-
-		log.Printf("Crawling: %s\n", currentURL)
-		html, err := c.Fetch(currentURL)
-		if err != nil {
-			log.Printf("Error fetching %s: %v\n", currentURL, err)
-			<-c.semaphore
-			continue
-		}
-
-		links, err := c.ParseLinks(html, baseURL)
-		if err != nil {
-			log.Printf("Error parsing links on %s: %v\n", currentURL, err)
-			<-c.semaphore
-			continue
-		}
+	if err := c.enqueue(domain, baseURL, 0); err != nil {
+		log.Printf("Error seeding %s: %v\n", domain, err)
+		return
+	}
+	c.jobWG.Wait()
+}
 
-		for _, link := range links {
-			if c.IsProductURL(link) {
-				c.mu.Lock()
-				c.ProductURLs[domain] = append(c.ProductURLs[domain], link)
-				c.mu.Unlock()
-			} else if !visited[link] {
-				toVisit = append(toVisit, link)
-			}
-		}
-		time.Sleep(c.RateLimit)
-		<-c.semaphore
+// urlHost extracts the host portion of a URL, returning "" on parse
+// failure so callers fall back to the default host bucket.
+func urlHost(link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
 	}
+	return parsed.Host
 }
 
-// SaveResults saves the crawled product URLs to a JSON file.
+// SaveResults writes the crawled product URLs to a JSON file. It is
+// entirely optional: callers that read results straight from Storage
+// (or stream them, see StreamCrawl) never need to call it.
 func (c *Crawler) SaveResults(filename string) {
-	file, err := json.MarshalIndent(c.ProductURLs, "", "  ")
+	products := make(map[string][]string, len(c.Domains))
+	for _, domain := range c.Domains {
+		products[domain] = c.Storage.Products(domain)
+	}
+
+	file, err := json.MarshalIndent(products, "", "  ")
 	if err != nil {
 		log.Fatalf("Error saving results: %v", err)
 	}
@@ -146,8 +289,14 @@ func (c *Crawler) SaveResults(filename string) {
 	log.Printf("Results saved to %s\n", filename)
 }
 
-// Run executes the crawler across all domains.
+// RunCrawler starts the worker pool once and crawls every configured
+// domain through it, returning once every domain's reachable pages
+// (bounded by MaxDepth/MaxPages) have been processed. It shuts the
+// worker pool down before returning, so the Crawler cannot be reused for
+// another crawl afterwards; callers should still defer Close to release
+// ArchiveTo resources.
 func (c *Crawler) RunCrawler() {
+	c.startWorkers()
 	wg := sync.WaitGroup{}
 	for _, domain := range c.Domains {
 		wg.Add(1)
@@ -157,17 +306,72 @@ func (c *Crawler) RunCrawler() {
 		}(domain)
 	}
 	wg.Wait()
+	c.shutdownWorkers()
 }
 
 func WebCrawler(c *gin.Context) {
 	domains := []string{"www.aliexpress.com"}
-	crawler := CreateNewCrawler(domains, 10*time.Millisecond, 50)
+	crawler := CreateNewCrawler(domains, 50)
+	crawler.Limit(LimitRule{
+		DomainGlob:  "*",
+		Delay:       10 * time.Millisecond,
+		Parallelism: 50,
+		RobotsTxt:   true,
+	})
+
+	storage, err := selectStorage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	crawler.Storage = storage
+	defer storage.Close()
+	defer crawler.Close()
+
 	crawler.RunCrawler()
 	crawler.SaveResults("product_urls.json")
 	filename := "product_urls.json"
 	c.File(filename)
 }
 
+// selectStorage picks a Storage backend for a request, checked in order:
+// the "storage" query param, then the CRAWLER_STORAGE environment
+// variable, falling back to an in-memory Storage. Recognized values are
+// "memory", "bolt" (using the "state_path" query param or
+// CRAWLER_BOLT_PATH env var, default "crawler-state.db"), and "redis"
+// (using "redis_addr" or CRAWLER_REDIS_ADDR, default "localhost:6379").
+func selectStorage(c *gin.Context) (Storage, error) {
+	backend := c.Query("storage")
+	if backend == "" {
+		backend = os.Getenv("CRAWLER_STORAGE")
+	}
+
+	switch backend {
+	case "", "memory":
+		return NewMemoryStorage(), nil
+	case "bolt":
+		path := c.Query("state_path")
+		if path == "" {
+			path = os.Getenv("CRAWLER_BOLT_PATH")
+		}
+		if path == "" {
+			path = "crawler-state.db"
+		}
+		return NewBoltStorage(path)
+	case "redis":
+		addr := c.Query("redis_addr")
+		if addr == "" {
+			addr = os.Getenv("CRAWLER_REDIS_ADDR")
+		}
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+		return NewRedisStorage(addr, "webcrawler")
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
+
 // resolveURL resolves a relative URL to an absolute URL.
 func resolveURL(baseURL, href string) string {
 	parsedBase, err := url.Parse(baseURL)